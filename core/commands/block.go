@@ -2,19 +2,21 @@ package commands
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
-	"os"
 
 	"github.com/ipfs/go-ipfs-cmds"
 	"github.com/ipfs/go-ipfs-cmds/cmdsutil"
-	"github.com/ipfs/go-ipfs/blocks"
-	util "github.com/ipfs/go-ipfs/blocks/blockstore/util"
+	"github.com/ipfs/go-ipfs/core/commands/cmdenv"
+	"github.com/ipfs/go-ipfs/core/coreapi"
+	iface "github.com/ipfs/go-ipfs/core/coreapi/interface"
+	"github.com/ipfs/go-ipfs/core/coreapi/interface/options"
 
-	mh "gx/ipfs/QmYDds3421prZgqKbLpEK7T9Aa2eVdQ7o3YarX1LVLdP2J/go-multihash"
-	//u "gx/ipfs/Qmb912gdngC1UWwTkhuW8knyRbcWeu5kqkxBpveLmW8bSr/go-ipfs-util"
 	cid "gx/ipfs/QmcTcsTvfaeEBRFo1TkFgT8sRmgi1n1LTZpecfVP8fzpGD/go-cid"
+	mbase "gx/ipfs/Qmakyf7usiMJ9SrCkpxfUhBXJZJo2nYVw9mVbxeJH1z9JW/go-multibase"
+	mh "gx/ipfs/QmYDds3421prZgqKbLpEK7T9Aa2eVdQ7o3YarX1LVLdP2J/go-multihash"
 )
 
 type BlockStat struct {
@@ -60,16 +62,33 @@ on raw IPFS blocks. It outputs the following to stdout:
 	Arguments: []cmdsutil.Argument{
 		cmdsutil.StringArg("key", true, false, "The base58 multihash of an existing block to stat.").EnableStdin(),
 	},
+	Options: []cmdsutil.Option{
+		cmdsutil.BoolOption("offline", "Don't fetch the block over the network if it's missing locally.").Default(false),
+	},
 	Run: func(req cmds.Request, re cmds.ResponseEmitter) {
-		b, err := getBlockForKey(req, req.Arguments()[0])
+		api, err := cmdenv.GetApi(req)
+		if err != nil {
+			re.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+
+		p, err := coreapi.ParsePath(req.Arguments()[0])
+		if err != nil {
+			re.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+
+		offline, _, _ := req.Option("offline").Bool()
+
+		b, err := api.Block().Stat(req.Context(), p, options.Block.Offline(offline))
 		if err != nil {
 			re.SetError(err, cmdsutil.ErrNormal)
 			return
 		}
 
 		re.Emit(&BlockStat{
-			Key:  b.Cid().String(),
-			Size: len(b.RawData()),
+			Key:  b.Path().Cid().String(),
+			Size: b.Size(),
 		})
 	},
 	Type: BlockStat{},
@@ -87,14 +106,31 @@ It outputs to stdout, and <key> is a base58 encoded multihash.
 	Arguments: []cmdsutil.Argument{
 		cmdsutil.StringArg("key", true, false, "The base58 multihash of an existing block to get.").EnableStdin(),
 	},
+	Options: []cmdsutil.Option{
+		cmdsutil.BoolOption("offline", "Don't fetch the block over the network if it's missing locally.").Default(false),
+	},
 	Run: func(req cmds.Request, re cmds.ResponseEmitter) {
-		b, err := getBlockForKey(req, req.Arguments()[0])
+		api, err := cmdenv.GetApi(req)
+		if err != nil {
+			re.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+
+		p, err := coreapi.ParsePath(req.Arguments()[0])
 		if err != nil {
 			re.SetError(err, cmdsutil.ErrNormal)
 			return
 		}
 
-		re.Emit(bytes.NewReader(b.RawData()))
+		offline, _, _ := req.Option("offline").Bool()
+
+		r, err := api.Block().Get(req.Context(), p, options.Block.Offline(offline))
+		if err != nil {
+			re.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+
+		re.Emit(r)
 	},
 }
 
@@ -108,95 +144,136 @@ It reads from stdin, and <key> is a base58 encoded multihash.
 	},
 
 	Arguments: []cmdsutil.Argument{
-		cmdsutil.FileArg("data", true, false, "The data to be stored as an IPFS block.").EnableStdin(),
+		cmdsutil.FileArg("data", true, true, "The data to be stored as an IPFS block.").EnableStdin(),
 	},
 	Options: []cmdsutil.Option{
 		cmdsutil.StringOption("format", "f", "cid format for blocks to be created with.").Default("v0"),
 		cmdsutil.StringOption("mhtype", "multihash hash function").Default("sha2-256"),
 		cmdsutil.IntOption("mhlen", "multihash hash length").Default(-1),
+		cmdsutil.BoolOption("pin", "pin added blocks recursively").Default(false),
+		cmdsutil.IntOption("cid-version", "cid version defaults: 1 makes --format/--mhtype default to raw/sha2-256, matching 'ipfs add --cid-version=1 --raw-leaves'; an explicit --format still decides the cid version.").Default(0),
+		cmdsutil.StringOption("cid-base", "multibase encoding to display the emitted keys in (base32, base58btc, base64url)."),
+		cmdsutil.StringOption("inputenc", "encoding of the input data: 'raw' (default) or 'json' for a manifest of blocks.").Default("raw"),
 	},
 	Run: func(req cmds.Request, re cmds.ResponseEmitter) {
-		n, err := req.InvocContext().GetNode()
-		if err != nil {
-			re.SetError(err, cmdsutil.ErrNormal)
-			return
-		}
-
-		file, err := req.Files().NextFile()
+		api, err := cmdenv.GetApi(req)
 		if err != nil {
 			re.SetError(err, cmdsutil.ErrNormal)
 			return
 		}
 
-		data, err := ioutil.ReadAll(file)
+		format, formatFound, _ := req.Option("format").String()
+		mhtype, mhtypeFound, _ := req.Option("mhtype").String()
+		cidVer, _, err := req.Option("cid-version").Int()
 		if err != nil {
 			re.SetError(err, cmdsutil.ErrNormal)
 			return
 		}
+		format, mhtype = cidVersionDefaults(cidVer, format, formatFound, mhtype, mhtypeFound)
 
-		err = file.Close()
-		if err != nil {
-			re.SetError(err, cmdsutil.ErrNormal)
-			return
-		}
-
-		var pref cid.Prefix
-		pref.Version = 1
-
-		format, _, _ := req.Option("format").String()
-		switch format {
-		case "cbor":
-			pref.Codec = cid.DagCBOR
-		case "protobuf":
-			pref.Codec = cid.DagProtobuf
-		case "raw":
-			pref.Codec = cid.Raw
-		case "v0":
-			pref.Version = 0
-			pref.Codec = cid.DagProtobuf
-		default:
-			re.SetError(fmt.Errorf("unrecognized format: %s", format), cmdsutil.ErrNormal)
-			return
-		}
-
-		mhtype, _, _ := req.Option("mhtype").String()
 		mhtval, ok := mh.Names[mhtype]
 		if !ok {
 			re.SetError(fmt.Errorf("unrecognized multihash function: %s", mhtype), cmdsutil.ErrNormal)
 			return
 		}
-		pref.MhType = mhtval
-
 		mhlen, _, err := req.Option("mhlen").Int()
 		if err != nil {
 			re.SetError(err, cmdsutil.ErrNormal)
 			return
 		}
-		pref.MhLength = mhlen
+		doPin, _, _ := req.Option("pin").Bool()
 
-		bcid, err := pref.Sum(data)
-		if err != nil {
-			re.SetError(err, cmdsutil.ErrNormal)
-			return
+		cidBase, cidBaseFound, _ := req.Option("cid-base").String()
+		var base mbase.Encoding
+		if cidBaseFound {
+			base, err = cidBaseEncoding(cidBase)
+			if err != nil {
+				re.SetError(err, cmdsutil.ErrNormal)
+				return
+			}
 		}
 
-		b, err := blocks.NewBlockWithCid(data, bcid)
-		if err != nil {
-			re.SetError(err, cmdsutil.ErrNormal)
-			return
-		}
-		log.Debugf("BlockPut key: '%q'", b.Cid())
+		inputenc, _, _ := req.Option("inputenc").String()
+		switch inputenc {
+		case "raw":
+			for {
+				file, err := req.Files().NextFile()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					re.SetError(err, cmdsutil.ErrNormal)
+					return
+				}
 
-		k, err := n.Blocks.AddBlock(b)
-		if err != nil {
-			re.SetError(err, cmdsutil.ErrNormal)
+				bs, err := putBlock(req, api, file, format, mhtval, mhlen, doPin, cidBaseFound, base)
+				if err != nil {
+					re.SetError(err, cmdsutil.ErrNormal)
+					return
+				}
+
+				if err := file.Close(); err != nil {
+					re.SetError(err, cmdsutil.ErrNormal)
+					return
+				}
+
+				if err := re.Emit(bs); err != nil {
+					re.SetError(err, cmdsutil.ErrNormal)
+					return
+				}
+			}
+		case "json":
+			file, err := req.Files().NextFile()
+			if err != nil {
+				re.SetError(err, cmdsutil.ErrNormal)
+				return
+			}
+
+			dec := json.NewDecoder(file)
+			if _, err := dec.Token(); err != nil { // consume the opening '['
+				re.SetError(fmt.Errorf("inputenc=json: expected a JSON array: %s", err), cmdsutil.ErrNormal)
+				return
+			}
+
+			for dec.More() {
+				var entry jsonBlock
+				if err := dec.Decode(&entry); err != nil {
+					re.SetError(err, cmdsutil.ErrNormal)
+					return
+				}
+
+				data, err := base64.StdEncoding.DecodeString(entry.Data)
+				if err != nil {
+					re.SetError(fmt.Errorf("inputenc=json: invalid base64 data: %s", err), cmdsutil.ErrNormal)
+					return
+				}
+
+				entryFormat, entryMhtval, entryMhlen, err := resolveJSONBlockDefaults(entry, format, mhtval, mhlen)
+				if err != nil {
+					re.SetError(err, cmdsutil.ErrNormal)
+					return
+				}
+
+				bs, err := putBlock(req, api, bytes.NewReader(data), entryFormat, entryMhtval, entryMhlen, doPin, cidBaseFound, base)
+				if err != nil {
+					re.SetError(err, cmdsutil.ErrNormal)
+					return
+				}
+
+				if err := re.Emit(bs); err != nil {
+					re.SetError(err, cmdsutil.ErrNormal)
+					return
+				}
+			}
+
+			if err := file.Close(); err != nil {
+				re.SetError(err, cmdsutil.ErrNormal)
+				return
+			}
+		default:
+			re.SetError(fmt.Errorf("unrecognized inputenc: %s", inputenc), cmdsutil.ErrNormal)
 			return
 		}
-
-		re.Emit(&BlockStat{
-			Key:  k.String(),
-			Size: len(data),
-		})
 	},
 	Encoders: map[cmds.EncodingType]func(cmds.Response) func(io.Writer) cmds.Encoder{
 		cmds.Text: cmds.MakeEncoder(func(w io.Writer, v interface{}) error {
@@ -208,30 +285,6 @@ It reads from stdin, and <key> is a base58 encoded multihash.
 	Type: BlockStat{},
 }
 
-func getBlockForKey(req cmds.Request, skey string) (blocks.Block, error) {
-	if len(skey) == 0 {
-		return nil, fmt.Errorf("zero length cid invalid")
-	}
-
-	n, err := req.InvocContext().GetNode()
-	if err != nil {
-		return nil, err
-	}
-
-	c, err := cid.Decode(skey)
-	if err != nil {
-		return nil, err
-	}
-
-	b, err := n.Blocks.GetBlock(req.Context(), c)
-	if err != nil {
-		return nil, err
-	}
-
-	log.Debugf("ipfs block: got block with key: %s", b.Cid())
-	return b, nil
-}
-
 var blockRmCmd = &cmds.Command{
 	Helptext: cmdsutil.HelpText{
 		Tagline: "Remove IPFS block(s).",
@@ -248,76 +301,162 @@ It takes a list of base58 encoded multihashs to remove.
 		cmdsutil.BoolOption("quiet", "q", "Write minimal output.").Default(false),
 	},
 	Run: func(req cmds.Request, re cmds.ResponseEmitter) {
-		n, err := req.InvocContext().GetNode()
+		api, err := cmdenv.GetApi(req)
 		if err != nil {
 			re.SetError(err, cmdsutil.ErrNormal)
 			return
 		}
-		hashes := req.Arguments()
+
 		force, _, _ := req.Option("force").Bool()
 		quiet, _, _ := req.Option("quiet").Bool()
-		cids := make([]*cid.Cid, 0, len(hashes))
-		for _, hash := range hashes {
-			c, err := cid.Decode(hash)
-			if err != nil {
-				re.SetError(fmt.Errorf("invalid content id: %s (%s)", hash, err), cmdsutil.ErrNormal)
-				return
-			}
 
-			cids = append(cids, c)
-		}
-		ch, err := util.RmBlocks(n.Blockstore, n.Pinning, cids, util.RmBlocksOpts{
-			Quiet: quiet,
-			Force: force,
-		})
-		if err != nil {
-			re.SetError(err, cmdsutil.ErrNormal)
-			return
-		}
+		hashes := req.Arguments()
 		go func() {
-			for v := range ch {
-				err := re.Emit(v)
+			someFailed := false
+			for _, hash := range hashes {
+				p, err := coreapi.ParsePath(hash)
 				if err != nil {
-					// TODO keks does that even work here? it definitely should!
-					re.SetError(err, cmdsutil.ErrNormal)
+					someFailed = true
+					re.Emit(&RemovedBlock{Hash: hash, Error: err.Error()})
+					continue
+				}
+
+				if err := api.Block().Rm(req.Context(), p, options.Block.Force(force)); err != nil {
+					someFailed = true
+					re.Emit(&RemovedBlock{Hash: hash, Error: err.Error()})
+					continue
+				}
+
+				if !quiet {
+					re.Emit(&RemovedBlock{Hash: hash})
 				}
 			}
+			if someFailed {
+				re.SetError(fmt.Errorf("some blocks not removed"), cmdsutil.ErrNormal)
+			}
+			re.Close()
 		}()
 	},
-	PostRun: map[cmds.EncodingType]func(cmds.Request, cmds.Response) cmds.Response{
-		cmds.Text: func(req cmds.Request, res cmds.Response) cmds.Response {
-			if res.Error() != nil {
-				return res
-			}
+	Type: RemovedBlock{},
+}
 
-			re, res_ := cmds.NewChanResponsePair(req)
+// RemovedBlock is the output type of 'ipfs block rm', reporting either the
+// hash of the block that was removed or the error that prevented it.
+type RemovedBlock struct {
+	Hash  string
+	Error string `json:",omitempty"`
+}
 
-			outChan := make(chan interface{})
+func (r *RemovedBlock) String() string {
+	if r.Error != "" {
+		return fmt.Sprintf("cannot remove %s: %s\n", r.Hash, r.Error)
+	}
+	return fmt.Sprintf("removed %s\n", r.Hash)
+}
 
-			go func() {
-				defer close(outChan)
+// jsonBlock is one entry of the manifest accepted by 'ipfs block put
+// --inputenc=json'.
+type jsonBlock struct {
+	Data   string `json:"data"`
+	Format string `json:"format"`
+	Mhtype string `json:"mhtype"`
+	Mhlen  int    `json:"mhlen"`
+}
 
-				for {
-					v, err := res.Next()
-					if err == io.EOF {
-						return
-					}
-					if err != nil {
-						re.SetError(err, cmdsutil.ErrNormal)
-						return
-					}
+// putBlock stores data as a single block using the given defaults and
+// returns the BlockStat to emit for it.
+func putBlock(req cmds.Request, api iface.CoreAPI, data io.Reader, format string, mhtval uint64, mhlen int, pin bool, cidBaseFound bool, base mbase.Encoding) (*BlockStat, error) {
+	p, err := api.Block().Put(req.Context(), data,
+		options.Block.Format(format),
+		options.Block.Hash(mhtval, mhlen),
+		options.Block.Pin(pin),
+	)
+	if err != nil {
+		return nil, err
+	}
 
-					outChan <- v
-				}
-			}()
+	b, err := api.Block().Stat(req.Context(), p)
+	if err != nil {
+		return nil, err
+	}
 
-			err := util.ProcRmOutput(outChan, os.Stdout, os.Stderr)
-			if err != nil {
-				re.SetError(err, cmdsutil.ErrNormal)
-			}
+	log.Debugf("BlockPut key: '%q'", p.Cid())
 
-			return res_
-		},
-	},
-	Type: util.RemovedBlock{},
+	key, err := blockKeyString(p.Cid(), cidBaseFound, base)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockStat{Key: key, Size: b.Size()}, nil
+}
+
+// blockKeyString renders c in the requested multibase, promoting a CIDv0 to
+// v1 first when a base other than base58btc is requested: CIDv0 can only be
+// encoded as base58btc, so StringOfBase would otherwise fail for the common
+// case of putting with the default --format=v0 and asking for --cid-base.
+func blockKeyString(c *cid.Cid, cidBaseFound bool, base mbase.Encoding) (string, error) {
+	if !cidBaseFound {
+		return c.String(), nil
+	}
+
+	if base != mbase.Base58BTC && c.Version() == 0 {
+		c = c.ToV1()
+	}
+
+	return c.StringOfBase(base)
+}
+
+// cidVersionDefaults applies 'ipfs add --cid-version=1 --raw-leaves' style
+// defaults to format/mhtype when cidVer is 1 and the caller didn't
+// explicitly set --format/--mhtype.
+func cidVersionDefaults(cidVer int, format string, formatFound bool, mhtype string, mhtypeFound bool) (string, string) {
+	if cidVer != 1 {
+		return format, mhtype
+	}
+	if !formatFound {
+		format = "raw"
+	}
+	if !mhtypeFound {
+		mhtype = "sha2-256"
+	}
+	return format, mhtype
+}
+
+// resolveJSONBlockDefaults returns the format/multihash to use for one entry
+// of an --inputenc=json manifest, falling back to the per-invocation
+// defaults for anything the entry didn't override.
+func resolveJSONBlockDefaults(entry jsonBlock, format string, mhtval uint64, mhlen int) (string, uint64, int, error) {
+	entryFormat := format
+	if entry.Format != "" {
+		entryFormat = entry.Format
+	}
+
+	entryMhtval := mhtval
+	if entry.Mhtype != "" {
+		v, ok := mh.Names[entry.Mhtype]
+		if !ok {
+			return "", 0, 0, fmt.Errorf("unrecognized multihash function: %s", entry.Mhtype)
+		}
+		entryMhtval = v
+	}
+
+	entryMhlen := mhlen
+	if entry.Mhlen != 0 {
+		entryMhlen = entry.Mhlen
+	}
+
+	return entryFormat, entryMhtval, entryMhlen, nil
+}
+
+func cidBaseEncoding(name string) (mbase.Encoding, error) {
+	switch name {
+	case "base32":
+		return mbase.Base32, nil
+	case "base58btc":
+		return mbase.Base58BTC, nil
+	case "base64url":
+		return mbase.Base64url, nil
+	default:
+		return 0, fmt.Errorf("unrecognized cid base encoding: %s", name)
+	}
 }
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"testing"
+
+	mbase "gx/ipfs/Qmakyf7usiMJ9SrCkpxfUhBXJZJo2nYVw9mVbxeJH1z9JW/go-multibase"
+	mh "gx/ipfs/QmYDds3421prZgqKbLpEK7T9Aa2eVdQ7o3YarX1LVLdP2J/go-multihash"
+
+	cid "gx/ipfs/QmcTcsTvfaeEBRFo1TkFgT8sRmgi1n1LTZpecfVP8fzpGD/go-cid"
+)
+
+func TestBlockKeyStringPromotesV0ForNonBase58Base(t *testing.T) {
+	mhash, err := mh.Sum([]byte("hello"), mh.SHA2_256, -1)
+	if err != nil {
+		t.Fatalf("mh.Sum: %s", err)
+	}
+	v0 := cid.NewCidV0(mhash)
+
+	// No --cid-base: the v0 CID's default String() form is used as-is.
+	key, err := blockKeyString(v0, false, 0)
+	if err != nil {
+		t.Fatalf("blockKeyString: %s", err)
+	}
+	if key != v0.String() {
+		t.Errorf("key = %q, want %q", key, v0.String())
+	}
+
+	// --cid-base=base32 on a v0 CID: StringOfBase would fail directly on a
+	// v0 CID, so it must be promoted to v1 first.
+	key, err = blockKeyString(v0, true, mbase.Base32)
+	if err != nil {
+		t.Fatalf("blockKeyString with base32: %s", err)
+	}
+	want, err := v0.ToV1().StringOfBase(mbase.Base32)
+	if err != nil {
+		t.Fatalf("ToV1().StringOfBase: %s", err)
+	}
+	if key != want {
+		t.Errorf("key = %q, want %q", key, want)
+	}
+
+	// --cid-base=base58btc on a v0 CID: base58btc needs no promotion.
+	key, err = blockKeyString(v0, true, mbase.Base58BTC)
+	if err != nil {
+		t.Fatalf("blockKeyString with base58btc: %s", err)
+	}
+	if key != v0.String() {
+		t.Errorf("key = %q, want %q", key, v0.String())
+	}
+}
+
+func TestCidVersionDefaults(t *testing.T) {
+	format, mhtype := cidVersionDefaults(1, "v0", false, "sha2-256", false)
+	if format != "raw" || mhtype != "sha2-256" {
+		t.Errorf("got format=%q mhtype=%q, want raw/sha2-256", format, mhtype)
+	}
+
+	// Explicit --format/--mhtype win over the cid-version=1 defaults.
+	format, mhtype = cidVersionDefaults(1, "cbor", true, "sha1", true)
+	if format != "cbor" || mhtype != "sha1" {
+		t.Errorf("got format=%q mhtype=%q, want explicit values preserved", format, mhtype)
+	}
+
+	// cid-version != 1 leaves everything untouched.
+	format, mhtype = cidVersionDefaults(0, "v0", false, "sha2-256", false)
+	if format != "v0" || mhtype != "sha2-256" {
+		t.Errorf("got format=%q mhtype=%q, want unchanged", format, mhtype)
+	}
+}
+
+func TestResolveJSONBlockDefaults(t *testing.T) {
+	mhtval, _ := mh.Names["sha2-256"]
+
+	// Entry with no overrides falls back to the manifest-wide defaults.
+	format, mhtv, mhlen, err := resolveJSONBlockDefaults(jsonBlock{}, "v0", mhtval, -1)
+	if err != nil {
+		t.Fatalf("resolveJSONBlockDefaults: %s", err)
+	}
+	if format != "v0" || mhtv != mhtval || mhlen != -1 {
+		t.Errorf("got format=%q mhtype=%d mhlen=%d, want defaults preserved", format, mhtv, mhlen)
+	}
+
+	// A mixed-format manifest entry overrides format/mhtype/mhlen per block.
+	entry := jsonBlock{Format: "raw", Mhtype: "sha1", Mhlen: 16}
+	format, mhtv, mhlen, err = resolveJSONBlockDefaults(entry, "v0", mhtval, -1)
+	if err != nil {
+		t.Fatalf("resolveJSONBlockDefaults: %s", err)
+	}
+	wantMhtval := mh.Names["sha1"]
+	if format != "raw" || mhtv != wantMhtval || mhlen != 16 {
+		t.Errorf("got format=%q mhtype=%d mhlen=%d, want raw/%d/16", format, mhtv, mhlen, wantMhtval)
+	}
+
+	// An unrecognized per-entry mhtype is rejected.
+	if _, _, _, err := resolveJSONBlockDefaults(jsonBlock{Mhtype: "bogus"}, "v0", mhtval, -1); err == nil {
+		t.Errorf("expected an error for an unrecognized mhtype, got none")
+	}
+}
+
+func TestCidBaseEncoding(t *testing.T) {
+	if _, err := cidBaseEncoding("bogus"); err == nil {
+		t.Errorf("expected an error for an unrecognized base, got none")
+	}
+	if b, err := cidBaseEncoding("base32"); err != nil || b != mbase.Base32 {
+		t.Errorf("cidBaseEncoding(base32) = %v, %v; want Base32, nil", b, err)
+	}
+}
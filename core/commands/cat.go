@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"fmt"
 	"io"
 	"os"
 
@@ -23,6 +24,10 @@ var CatCmd = &cmds.Command{
 	Arguments: []cmdsutil.Argument{
 		cmdsutil.StringArg("ipfs-path", true, true, "The path to the IPFS object(s) to be outputted.").EnableStdin(),
 	},
+	Options: []cmdsutil.Option{
+		cmdsutil.IntOption("offset", "o", "Byte offset to begin reading from."),
+		cmdsutil.IntOption("length", "l", "Maximum number of bytes to read."),
+	},
 	Run: func(req cmds.Request, re cmds.ResponseEmitter) {
 		log.Debugf("cat: RespEm type is %T", re)
 		node, err := req.InvocContext().GetNode()
@@ -38,7 +43,33 @@ var CatCmd = &cmds.Command{
 			}
 		}
 
-		readers, length, err := cat(req.Context(), node, req.Arguments())
+		offset, _, err := req.Option("offset").Int()
+		if err != nil {
+			re.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		if offset < 0 {
+			re.SetError(fmt.Errorf("cat: offset must be non-negative"), cmdsutil.ErrNormal)
+			return
+		}
+
+		length, _, err := req.Option("length").Int()
+		if err != nil {
+			re.SetError(err, cmdsutil.ErrNormal)
+			return
+		}
+		if length < 0 {
+			re.SetError(fmt.Errorf("cat: length must be non-negative"), cmdsutil.ErrNormal)
+			return
+		}
+
+		paths := req.Arguments()
+		if (offset != 0 || length != 0) && len(paths) != 1 {
+			re.SetError(fmt.Errorf("cat: --offset and --length require exactly one path"), cmdsutil.ErrNormal)
+			return
+		}
+
+		readers, totalLength, err := cat(req.Context(), node, paths, int64(offset), int64(length))
 		log.Debug("cat returned ", err)
 
 		if err != nil {
@@ -47,13 +78,13 @@ var CatCmd = &cmds.Command{
 		}
 
 		/*
-			if err := corerepo.ConditionalGC(req.Context(), node, length); err != nil {
+			if err := corerepo.ConditionalGC(req.Context(), node, totalLength); err != nil {
 				res.SetError(err, cmdsutil.ErrNormal)
 				return
 			}
 		*/
 
-		re.SetLength(length)
+		re.SetLength(totalLength)
 
 		reader := io.MultiReader(readers...)
 		go func() {
@@ -87,16 +118,19 @@ var CatCmd = &cmds.Command{
 	},
 }
 
-func cat(ctx context.Context, node *core.IpfsNode, paths []string) ([]io.Reader, uint64, error) {
+func cat(ctx context.Context, node *core.IpfsNode, paths []string, offset, length int64) ([]io.Reader, uint64, error) {
 	readers := make([]io.Reader, 0, len(paths))
-	length := uint64(0)
+	total := uint64(0)
 	for _, fpath := range paths {
-		read, err := coreunix.Cat(ctx, node, fpath)
+		// fpath is passed through as-is (not pre-parsed via coreapi.ParsePath)
+		// so that non-CID IPNS roots (e.g. DNSLink names) keep resolving the
+		// way core.Resolve has always handled them.
+		read, err := coreunix.Cat(ctx, node, fpath, offset, length)
 		if err != nil {
 			return nil, 0, err
 		}
 		readers = append(readers, read)
-		length += uint64(read.Size())
+		total += uint64(read.Size())
 	}
-	return readers, length, nil
+	return readers, total, nil
 }
@@ -0,0 +1,20 @@
+// Package cmdenv provides helpers commands use to pull shared state (the
+// node, the CoreAPI, ...) out of a command's invocation context.
+package cmdenv
+
+import (
+	"github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/ipfs/go-ipfs/core/coreapi"
+	iface "github.com/ipfs/go-ipfs/core/coreapi/interface"
+)
+
+// GetApi returns the CoreAPI for the node backing this request.
+func GetApi(req cmds.Request) (iface.CoreAPI, error) {
+	n, err := req.InvocContext().GetNode()
+	if err != nil {
+		return nil, err
+	}
+
+	return coreapi.NewCoreAPI(n), nil
+}
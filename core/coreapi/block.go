@@ -0,0 +1,164 @@
+package coreapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	iface "github.com/ipfs/go-ipfs/core/coreapi/interface"
+	"github.com/ipfs/go-ipfs/core/coreapi/interface/options"
+
+	"github.com/ipfs/go-ipfs/blocks"
+	bstutil "github.com/ipfs/go-ipfs/blocks/blockstore/util"
+	"github.com/ipfs/go-ipfs/pin"
+
+	cid "gx/ipfs/QmcTcsTvfaeEBRFo1TkFgT8sRmgi1n1LTZpecfVP8fzpGD/go-cid"
+)
+
+type blockStat struct {
+	path iface.Path
+	size int
+}
+
+func (bs *blockStat) Size() int {
+	return bs.size
+}
+
+func (bs *blockStat) Path() iface.Path {
+	return bs.path
+}
+
+// Put implements iface.BlockAPI.
+func (api *BlockAPI) Put(ctx context.Context, src io.Reader, opts ...options.BlockPutOption) (iface.Path, error) {
+	settings, err := options.BlockPutOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	bcid, err := settings.CidPrefix.Sum(data)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := blocks.NewBlockWithCid(data, bcid)
+	if err != nil {
+		return nil, err
+	}
+
+	if !settings.Pin {
+		if _, err := api.node.Blocks.AddBlock(b); err != nil {
+			return nil, err
+		}
+
+		return ParsePath(b.Cid().String())
+	}
+
+	// Pin the block in the same transaction as adding it, taking the
+	// pinner lock first so a concurrent GC can't collect it in between.
+	unlocker := api.node.Blockstore.PinLock()
+	defer unlocker.Unlock()
+
+	if _, err := api.node.Blocks.AddBlock(b); err != nil {
+		return nil, err
+	}
+
+	if err := api.node.Pinning.PinWithMode(b.Cid(), pin.Recursive); err != nil {
+		bstutil.RmBlocks(api.node.Blockstore, api.node.Pinning, []*cid.Cid{b.Cid()}, bstutil.RmBlocksOpts{Force: true, Quiet: true})
+		return nil, err
+	}
+
+	if err := api.node.Pinning.Flush(); err != nil {
+		bstutil.RmBlocks(api.node.Blockstore, api.node.Pinning, []*cid.Cid{b.Cid()}, bstutil.RmBlocksOpts{Force: true, Quiet: true})
+		return nil, err
+	}
+
+	return ParsePath(b.Cid().String())
+}
+
+// Get implements iface.BlockAPI.
+func (api *BlockAPI) Get(ctx context.Context, p iface.Path, opts ...options.BlockGetOption) (io.Reader, error) {
+	settings, err := options.BlockGetOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := api.getBlock(ctx, p, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(b.RawData()), nil
+}
+
+// Rm implements iface.BlockAPI.
+func (api *BlockAPI) Rm(ctx context.Context, p iface.Path, opts ...options.BlockRmOption) error {
+	settings, err := options.BlockRmOptions(opts...)
+	if err != nil {
+		return err
+	}
+
+	c := p.Cid()
+	if c == nil {
+		return fmt.Errorf("invalid block path %q", p.String())
+	}
+
+	res, err := bstutil.RmBlocks(api.node.Blockstore, api.node.Pinning, []*cid.Cid{c}, bstutil.RmBlocksOpts{
+		Force: settings.Force,
+		Quiet: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	for r := range res {
+		rb := r.(*bstutil.RemovedBlock)
+		if rb.Error != "" {
+			return fmt.Errorf(rb.Error)
+		}
+	}
+
+	return nil
+}
+
+// Stat implements iface.BlockAPI.
+func (api *BlockAPI) Stat(ctx context.Context, p iface.Path, opts ...options.BlockGetOption) (iface.BlockStat, error) {
+	settings, err := options.BlockGetOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := api.getBlock(ctx, p, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	bp, err := ParsePath(b.Cid().String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &blockStat{path: bp, size: len(b.RawData())}, nil
+}
+
+// getBlock fetches the block for p through the node's block exchange (e.g.
+// bitswap), or from the local blockstore only when settings.Offline is set.
+// No other exchange can be selected per call.
+func (api *BlockAPI) getBlock(ctx context.Context, p iface.Path, settings *options.BlockGetSettings) (blocks.Block, error) {
+	c := p.Cid()
+	if c == nil {
+		return nil, fmt.Errorf("invalid block path %q", p.String())
+	}
+
+	if settings.Offline {
+		return api.node.Blockstore.Get(c)
+	}
+
+	return api.node.Blocks.GetBlock(ctx, c)
+}
@@ -0,0 +1,27 @@
+// Package coreapi implements the CoreAPI (see
+// github.com/ipfs/go-ipfs/core/coreapi/interface) on top of an in-process
+// IpfsNode.
+package coreapi
+
+import (
+	"github.com/ipfs/go-ipfs/core"
+	iface "github.com/ipfs/go-ipfs/core/coreapi/interface"
+)
+
+// CoreAPI implements iface.CoreAPI on top of a local IpfsNode.
+type CoreAPI struct {
+	node *core.IpfsNode
+}
+
+// NewCoreAPI creates a CoreAPI backed by the given node.
+func NewCoreAPI(n *core.IpfsNode) iface.CoreAPI {
+	return &CoreAPI{node: n}
+}
+
+// BlockAPI implements iface.BlockAPI.
+type BlockAPI CoreAPI
+
+// Block returns the BlockAPI for this node.
+func (api *CoreAPI) Block() iface.BlockAPI {
+	return (*BlockAPI)(api)
+}
@@ -0,0 +1,63 @@
+// Package iface defines the CoreAPI interface that plumbing commands use to
+// talk to an IPFS node, instead of reaching into the node's internals
+// (blockstore, pinner, exchange, ...) directly.
+package iface
+
+import (
+	"context"
+	"io"
+
+	"github.com/ipfs/go-ipfs/core/coreapi/interface/options"
+
+	cid "gx/ipfs/QmcTcsTvfaeEBRFo1TkFgT8sRmgi1n1LTZpecfVP8fzpGD/go-cid"
+)
+
+// Path is an opaque handle to a path rooted on an IPFS/IPNS CID, as accepted
+// on the command line ("/ipfs/<cid>", "/ipns/...", or a bare CID).
+type Path interface {
+	// String returns the path as a string.
+	String() string
+
+	// Cid returns the CID the path is rooted on.
+	Cid() *cid.Cid
+}
+
+// BlockStat contains information about a block.
+type BlockStat interface {
+	// Size is the size of the block in bytes.
+	Size() int
+
+	// Path is the path (/ipfs/<cid>) of the block.
+	Path() Path
+}
+
+// BlockAPI specifies the interface to the block layer, used to get, put, rm
+// and stat raw IPFS blocks. Get, Rm and Stat act on the block named by the
+// path's root CID (Path.Cid): neither further path segments nor /ipns/ names
+// are resolved.
+type BlockAPI interface {
+	// Put imports raw block data, hashing and wrapping it according to the
+	// given options, and returns the path of the resulting block.
+	Put(context.Context, io.Reader, ...options.BlockPutOption) (Path, error)
+
+	// Get returns a reader for the raw data of the block referenced by path.
+	// By default it may fetch the block from the node's block exchange
+	// (e.g. bitswap); pass options.Block.Offline(true) to restrict the
+	// lookup to the local blockstore for this call only.
+	Get(context.Context, Path, ...options.BlockGetOption) (io.Reader, error)
+
+	// Rm removes the block referenced by path from the local blockstore.
+	Rm(context.Context, Path, ...options.BlockRmOption) error
+
+	// Stat returns information about the block referenced by path. It
+	// accepts the same exchange-selection options as Get.
+	Stat(context.Context, Path, ...options.BlockGetOption) (BlockStat, error)
+}
+
+// CoreAPI defines an unified interface to IPFS for Go programs, used by
+// plumbing commands so they don't need to know how the underlying node is
+// wired up.
+type CoreAPI interface {
+	// Block returns an implementation of BlockAPI.
+	Block() BlockAPI
+}
@@ -0,0 +1,154 @@
+package options
+
+import (
+	"fmt"
+
+	cid "gx/ipfs/QmcTcsTvfaeEBRFo1TkFgT8sRmgi1n1LTZpecfVP8fzpGD/go-cid"
+	mh "gx/ipfs/QmYDds3421prZgqKbLpEK7T9Aa2eVdQ7o3YarX1LVLdP2J/go-multihash"
+)
+
+// BlockPutSettings are the settings a BlockPutOption can change.
+type BlockPutSettings struct {
+	CidPrefix cid.Prefix
+	Pin       bool
+}
+
+// BlockRmSettings are the settings a BlockRmOption can change.
+type BlockRmSettings struct {
+	Force bool
+}
+
+// BlockGetSettings are the settings a BlockGetOption can change. They select
+// which block exchange backs Block.Get/Block.Stat for that call.
+type BlockGetSettings struct {
+	Offline bool
+}
+
+// BlockPutOption is a single option for Block.Put.
+type BlockPutOption func(*BlockPutSettings) error
+
+// BlockRmOption is a single option for Block.Rm.
+type BlockRmOption func(*BlockRmSettings) error
+
+// BlockGetOption is a single option for Block.Get/Block.Stat.
+type BlockGetOption func(*BlockGetSettings) error
+
+// BlockPutOptions applies the given options and returns a fully populated
+// BlockPutSettings.
+func BlockPutOptions(opts ...BlockPutOption) (*BlockPutSettings, error) {
+	options := &BlockPutSettings{
+		CidPrefix: cid.Prefix{
+			Version:  1,
+			Codec:    cid.DagProtobuf,
+			MhType:   mh.SHA2_256,
+			MhLength: -1,
+		},
+		Pin: false,
+	}
+
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+// BlockRmOptions applies the given options and returns a fully populated
+// BlockRmSettings.
+func BlockRmOptions(opts ...BlockRmOption) (*BlockRmSettings, error) {
+	options := &BlockRmSettings{
+		Force: false,
+	}
+
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+// BlockGetOptions applies the given options and returns a fully populated
+// BlockGetSettings.
+func BlockGetOptions(opts ...BlockGetOption) (*BlockGetSettings, error) {
+	options := &BlockGetSettings{
+		Offline: false,
+	}
+
+	for _, opt := range opts {
+		if err := opt(options); err != nil {
+			return nil, err
+		}
+	}
+
+	return options, nil
+}
+
+type blockOpts struct{}
+
+// Block groups the option functions usable with the BlockAPI.
+var Block blockOpts
+
+// Format sets the codec ("cbor", "protobuf", "raw" or "v0") of the CID
+// generated for a block put.
+func (blockOpts) Format(codec string) BlockPutOption {
+	return func(settings *BlockPutSettings) error {
+		switch codec {
+		case "cbor":
+			settings.CidPrefix.Version = 1
+			settings.CidPrefix.Codec = cid.DagCBOR
+		case "protobuf":
+			settings.CidPrefix.Version = 1
+			settings.CidPrefix.Codec = cid.DagProtobuf
+		case "raw":
+			settings.CidPrefix.Version = 1
+			settings.CidPrefix.Codec = cid.Raw
+		case "v0":
+			settings.CidPrefix.Version = 0
+			settings.CidPrefix.Codec = cid.DagProtobuf
+		default:
+			return fmt.Errorf("unrecognized format: %s", codec)
+		}
+		return nil
+	}
+}
+
+// Hash sets the multihash function and length used for a block put. A
+// negative mhLen means the hash function's default length.
+func (blockOpts) Hash(mhType uint64, mhLen int) BlockPutOption {
+	return func(settings *BlockPutSettings) error {
+		settings.CidPrefix.MhType = mhType
+		settings.CidPrefix.MhLength = mhLen
+		return nil
+	}
+}
+
+// Pin sets whether the block should be recursively pinned as part of the
+// same put.
+func (blockOpts) Pin(pin bool) BlockPutOption {
+	return func(settings *BlockPutSettings) error {
+		settings.Pin = pin
+		return nil
+	}
+}
+
+// Force makes Block.Rm ignore blocks that don't exist.
+func (blockOpts) Force(force bool) BlockRmOption {
+	return func(settings *BlockRmSettings) error {
+		settings.Force = force
+		return nil
+	}
+}
+
+// Offline restricts Block.Get/Block.Stat to the local blockstore for this
+// call, bypassing the node's block exchange (e.g. bitswap) even if the node
+// is online.
+func (blockOpts) Offline(offline bool) BlockGetOption {
+	return func(settings *BlockGetSettings) error {
+		settings.Offline = offline
+		return nil
+	}
+}
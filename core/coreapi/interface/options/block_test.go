@@ -0,0 +1,23 @@
+package options
+
+import "testing"
+
+func TestBlockGetOptionsDefaultsToOnline(t *testing.T) {
+	settings, err := BlockGetOptions()
+	if err != nil {
+		t.Fatalf("BlockGetOptions: %s", err)
+	}
+	if settings.Offline {
+		t.Errorf("Offline = true, want false by default")
+	}
+}
+
+func TestBlockOffline(t *testing.T) {
+	settings, err := BlockGetOptions(Block.Offline(true))
+	if err != nil {
+		t.Fatalf("BlockGetOptions: %s", err)
+	}
+	if !settings.Offline {
+		t.Errorf("Offline = false, want true")
+	}
+}
@@ -0,0 +1,58 @@
+package coreapi
+
+import (
+	"fmt"
+	"strings"
+
+	iface "github.com/ipfs/go-ipfs/core/coreapi/interface"
+
+	cid "gx/ipfs/QmcTcsTvfaeEBRFo1TkFgT8sRmgi1n1LTZpecfVP8fzpGD/go-cid"
+)
+
+// path is the iface.Path implementation used by this package. The root
+// segment (/ipfs/<cid> or /ipns/<cid>) must decode to a CID directly; any
+// remaining segments are kept verbatim in String(), while Cid() returns the
+// root CID only.
+type path struct {
+	path string
+	cid  *cid.Cid
+}
+
+func (p *path) String() string {
+	return p.path
+}
+
+func (p *path) Cid() *cid.Cid {
+	return p.cid
+}
+
+// ParsePath parses "/ipfs/<cid>[/...]", "/ipns/<cid>[/...]" and bare CID
+// strings into an iface.Path.
+func ParsePath(p string) (iface.Path, error) {
+	if len(p) == 0 {
+		return nil, fmt.Errorf("paths must not be empty")
+	}
+
+	segment := p
+	ns := "/ipfs/"
+	switch {
+	case strings.HasPrefix(p, "/ipfs/"):
+		segment = p[len("/ipfs/"):]
+	case strings.HasPrefix(p, "/ipns/"):
+		segment = p[len("/ipns/"):]
+		ns = "/ipns/"
+	}
+
+	root := segment
+	rest := ""
+	if i := strings.Index(segment, "/"); i >= 0 {
+		root, rest = segment[:i], segment[i:]
+	}
+
+	c, err := cid.Decode(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %s", p, err)
+	}
+
+	return &path{path: ns + c.String() + rest, cid: c}, nil
+}
@@ -0,0 +1,102 @@
+package coreunix
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	core "github.com/ipfs/go-ipfs/core"
+	path "github.com/ipfs/go-ipfs/path"
+	uio "github.com/ipfs/go-ipfs/unixfs/io"
+)
+
+// Cat returns a DagReader for the UnixFS file at fpath, seeked to offset and
+// capped at length bytes. length <= 0 means "read to the end of the file".
+func Cat(ctx context.Context, n *core.IpfsNode, fpath string, offset, length int64) (uio.DagReader, error) {
+	dagNode, err := core.Resolve(ctx, n.Namesys, n.Resolver, path.Path(fpath))
+	if err != nil {
+		return nil, err
+	}
+
+	dr, err := uio.NewDagReader(ctx, dagNode, n.DAG)
+	if err != nil {
+		return nil, err
+	}
+
+	clamped, wrap, err := catRange(dr.Size(), offset, length)
+	if err != nil {
+		return nil, err
+	}
+	if !wrap {
+		return dr, nil
+	}
+
+	if offset > 0 {
+		if _, err := dr.Seek(offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+	}
+
+	return &limitedDagReader{
+		dr:    dr,
+		limit: io.LimitReader(dr, int64(clamped)),
+		size:  clamped,
+	}, nil
+}
+
+// catRange validates offset/length against size and returns the number of
+// bytes that should be read (size - offset, capped by length) along with
+// whether the result needs to be enforced by wrapping dr in a
+// limitedDagReader (i.e. it's smaller than what dr would return on its own).
+func catRange(size uint64, offset, length int64) (clamped uint64, wrap bool, err error) {
+	if offset > int64(size) {
+		return 0, false, fmt.Errorf("cat: offset %d is larger than the file size %d", offset, size)
+	}
+
+	if offset == 0 && (length <= 0 || length >= int64(size)) {
+		return size, false, nil
+	}
+
+	remaining := int64(size) - offset
+	clampedLen := remaining
+	if length > 0 && length < remaining {
+		clampedLen = length
+	}
+
+	return uint64(clampedLen), true, nil
+}
+
+// limitedDagReader wraps a uio.DagReader to cap the number of bytes Read (and
+// WriteTo) returns, and to report the clamped size, so callers like 'ipfs cat
+// --offset/--length' size progress bars and Content-Length headers
+// correctly. It deliberately does not embed uio.DagReader: doing so would
+// promote its WriteTo method, which would stream past the cap.
+type limitedDagReader struct {
+	dr    uio.DagReader
+	limit io.Reader
+	size  uint64
+}
+
+func (r *limitedDagReader) Read(p []byte) (int, error) {
+	return r.limit.Read(p)
+}
+
+func (r *limitedDagReader) CtxReadFull(ctx context.Context, p []byte) (int, error) {
+	return io.ReadFull(r.limit, p)
+}
+
+func (r *limitedDagReader) WriteTo(w io.Writer) (int64, error) {
+	return io.Copy(w, r.limit)
+}
+
+func (r *limitedDagReader) Seek(offset int64, whence int) (int64, error) {
+	return r.dr.Seek(offset, whence)
+}
+
+func (r *limitedDagReader) Close() error {
+	return r.dr.Close()
+}
+
+func (r *limitedDagReader) Size() uint64 {
+	return r.size
+}
@@ -0,0 +1,124 @@
+package coreunix
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// fakeDagReader is a minimal uio.DagReader backed by an in-memory buffer,
+// used to test limitedDagReader without a real *core.IpfsNode/DAG.
+type fakeDagReader struct {
+	*bytes.Reader
+	size uint64
+}
+
+func (r *fakeDagReader) Size() uint64 {
+	return r.size
+}
+
+func (r *fakeDagReader) CtxReadFull(ctx context.Context, p []byte) (int, error) {
+	return io.ReadFull(r, p)
+}
+
+func (r *fakeDagReader) Close() error {
+	return nil
+}
+
+func newFakeDagReader(data []byte) *fakeDagReader {
+	return &fakeDagReader{Reader: bytes.NewReader(data), size: uint64(len(data))}
+}
+
+func TestCatRange(t *testing.T) {
+	cases := []struct {
+		name    string
+		size    uint64
+		offset  int64
+		length  int64
+		clamped uint64
+		wrap    bool
+		wantErr bool
+	}{
+		{"whole file", 10, 0, 0, 10, false, false},
+		{"length covers whole file", 10, 0, 10, 10, false, false},
+		{"length past end of file", 10, 0, 20, 10, false, false},
+		{"offset only", 10, 4, 0, 6, true, false},
+		{"offset and length", 10, 2, 3, 3, true, false},
+		{"offset equal to size", 10, 10, 0, 0, true, false},
+		{"offset past end of file", 10, 11, 0, 0, false, true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			clamped, wrap, err := catRange(c.size, c.offset, c.length)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if clamped != c.clamped {
+				t.Errorf("clamped = %d, want %d", clamped, c.clamped)
+			}
+			if wrap != c.wrap {
+				t.Errorf("wrap = %v, want %v", wrap, c.wrap)
+			}
+		})
+	}
+}
+
+func TestLimitedDagReaderCapsRead(t *testing.T) {
+	data := []byte("0123456789")
+	dr := newFakeDagReader(data)
+
+	// Mimic what Cat does for offset=2, length=3: seek past the offset and
+	// cap reads/writes at 3 bytes.
+	if _, err := dr.Seek(2, io.SeekStart); err != nil {
+		t.Fatalf("seek: %s", err)
+	}
+	r := &limitedDagReader{
+		dr:    dr,
+		limit: io.LimitReader(dr, 3),
+		size:  3,
+	}
+
+	if r.Size() != 3 {
+		t.Fatalf("Size() = %d, want 3", r.Size())
+	}
+
+	out, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll: %s", err)
+	}
+	if string(out) != "234" {
+		t.Fatalf("Read returned %q, want %q", out, "234")
+	}
+}
+
+func TestLimitedDagReaderWriteToDoesNotBypassCap(t *testing.T) {
+	data := []byte("0123456789")
+	dr := newFakeDagReader(data)
+
+	r := &limitedDagReader{
+		dr:    dr,
+		limit: io.LimitReader(dr, 4),
+		size:  4,
+	}
+
+	var buf bytes.Buffer
+	n, err := r.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo: %s", err)
+	}
+	if n != 4 {
+		t.Fatalf("WriteTo wrote %d bytes, want 4", n)
+	}
+	if buf.String() != "0123" {
+		t.Fatalf("WriteTo wrote %q, want %q (should not bypass the --length cap)", buf.String(), "0123")
+	}
+}